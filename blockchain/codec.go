@@ -1,16 +1,86 @@
 package blockchain
 
 import (
-	"errors"
+	"fmt"
 
 	bcproto "github.com/tendermint/tendermint/proto/blockchain"
 	"github.com/tendermint/tendermint/types"
 )
 
+const (
+	// ProtocolVersionV0 is the original, unversioned wire format: a bare
+	// bcproto.Message with no envelope.
+	ProtocolVersionV0 uint32 = 0
+
+	// ProtocolVersionV1 adds the bcproto.Envelope wrapper (ProtocolVersion
+	// field, ChunkedBlockResponse, NoBlockResponse reason codes, and
+	// StatusResponse.Base). It is the version this node sends.
+	ProtocolVersionV1 uint32 = 1
+
+	currentProtocolVersion = ProtocolVersionV1
+
+	// maxMsgSize is the strict upper bound enforced on any encoded message
+	// this reactor will decode, to bound memory use from a misbehaving or
+	// malicious peer.
+	maxMsgSize = 100 * 1024 * 1024 // 100MB, generous enough for the largest block plus overhead
+)
+
+// EncodeMessage wraps m in a versioned Envelope and marshals it to bytes
+// for sending on the wire.
+func EncodeMessage(m Message) ([]byte, error) {
+	pb, err := MsgToProto(m)
+	if err != nil {
+		return nil, err
+	}
+
+	env := bcproto.Envelope{
+		ProtocolVersion: currentProtocolVersion,
+		Sum:             pb.Sum,
+	}
+
+	bz, err := env.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if len(bz) > maxMsgSize {
+		return nil, fmt.Errorf("encoded message (%d bytes) exceeds maxMsgSize (%d bytes)", len(bz), maxMsgSize)
+	}
+	return bz, nil
+}
+
+// DecodeMessage unmarshals bytes received on the wire into a Message,
+// rejecting oversized payloads and unknown protocol versions before doing
+// any further work. It understands both the wire formats this reactor may
+// see in the wild: the versioned Envelope this node sends, and the bare,
+// unwrapped bcproto.Message a peer still running the pre-Envelope (v0)
+// reactor sends.
+func DecodeMessage(bz []byte) (Message, error) {
+	if len(bz) > maxMsgSize {
+		return nil, fmt.Errorf("received message (%d bytes) exceeds maxMsgSize (%d bytes)", len(bz), maxMsgSize)
+	}
+
+	var env bcproto.Envelope
+	if err := env.Unmarshal(bz); err == nil && env.Sum != nil {
+		switch env.ProtocolVersion {
+		case ProtocolVersionV0, ProtocolVersionV1:
+			return MsgFromProto(bcproto.Message{Sum: env.Sum})
+		default:
+			return nil, fmt.Errorf("unsupported blockchain protocol version %d", env.ProtocolVersion)
+		}
+	}
+
+	// Not a (recognizable) Envelope: fall back to the legacy v0 format,
+	// where bz is a bare bcproto.Message with no wrapper at all.
+	var msg bcproto.Message
+	if err := msg.Unmarshal(bz); err != nil {
+		return nil, err
+	}
+	return MsgFromProto(msg)
+}
+
 func MsgToProto(bcm Message) (*bcproto.Message, error) {
 	switch msg := bcm.(type) {
 	case *BlockRequestMessage:
-		// bm := BlockRequestMessage{Height: msg.BlockRequest.Height}
 		bm := bcproto.Message{
 			Sum: &bcproto.Message_BlockRequest{
 				BlockRequest: &bcproto.BlockRequest{
@@ -33,16 +103,29 @@ func MsgToProto(bcm Message) (*bcproto.Message, error) {
 			},
 		}
 		return &bm, nil
+	case *ChunkedBlockResponseMessage:
+		bm := bcproto.Message{
+			Sum: &bcproto.Message_ChunkedBlockResponse{
+				ChunkedBlockResponse: &bcproto.ChunkedBlockResponse{
+					Height: msg.Height,
+					Index:  msg.Index,
+					Parts:  msg.Parts,
+					Chunk:  msg.Chunk,
+				},
+			},
+		}
+		return &bm, nil
 	case *NoBlockResponseMessage:
 		bm := bcproto.Message{
 			Sum: &bcproto.Message_NoBlockResponse{
 				NoBlockResponse: &bcproto.NoBlockResponse{
 					Height: msg.Height,
+					Reason: msg.Reason,
 				},
 			},
 		}
 		return &bm, nil
-	case *StatusResponseMessage:
+	case *StatusRequestMessage:
 		bm := bcproto.Message{
 			Sum: &bcproto.Message_StatusRequest{
 				StatusRequest: &bcproto.StatusRequest{
@@ -51,17 +134,60 @@ func MsgToProto(bcm Message) (*bcproto.Message, error) {
 			},
 		}
 		return &bm, nil
-	case *StatusRequestMessage:
+	case *StatusResponseMessage:
 		bm := bcproto.Message{
 			Sum: &bcproto.Message_StatusResponse{
 				StatusResponse: &bcproto.StatusResponse{
 					Height: msg.Height,
+					Base:   msg.Base,
+				},
+			},
+		}
+		return &bm, nil
+	case *SnapshotRequestMessage:
+		bm := bcproto.Message{
+			Sum: &bcproto.Message_SnapshotRequest{
+				SnapshotRequest: &bcproto.SnapshotRequest{},
+			},
+		}
+		return &bm, nil
+	case *SnapshotResponseMessage:
+		bm := bcproto.Message{
+			Sum: &bcproto.Message_SnapshotResponse{
+				SnapshotResponse: &bcproto.SnapshotResponse{
+					Height:      msg.Height,
+					Format:      msg.Format,
+					NumChunks:   msg.NumChunks,
+					ChunkHashes: msg.ChunkHashes,
+				},
+			},
+		}
+		return &bm, nil
+	case *ChunkRequestMessage:
+		bm := bcproto.Message{
+			Sum: &bcproto.Message_ChunkRequest{
+				ChunkRequest: &bcproto.ChunkRequest{
+					Height: msg.Height,
+					Format: msg.Format,
+					Index:  msg.Index,
+				},
+			},
+		}
+		return &bm, nil
+	case *ChunkResponseMessage:
+		bm := bcproto.Message{
+			Sum: &bcproto.Message_ChunkResponse{
+				ChunkResponse: &bcproto.ChunkResponse{
+					Height: msg.Height,
+					Format: msg.Format,
+					Index:  msg.Index,
+					Chunk:  msg.Chunk,
 				},
 			},
 		}
 		return &bm, nil
 	default:
-		return nil, errors.New("evidence is not recognized")
+		return nil, fmt.Errorf("blockchain message %T is not recognized", bcm)
 	}
 }
 
@@ -74,7 +200,7 @@ func MsgFromProto(bcm bcproto.Message) (Message, error) {
 		}
 		return &bm, nil
 	case *bcproto.Message_NoBlockResponse:
-		bm := NoBlockResponseMessage{Height: msg.NoBlockResponse.Height}
+		bm := NoBlockResponseMessage{Height: msg.NoBlockResponse.Height, Reason: msg.NoBlockResponse.Reason}
 		if err := bm.ValidateBasic(); err != nil {
 			return nil, err
 		}
@@ -89,6 +215,17 @@ func MsgFromProto(bcm bcproto.Message) (Message, error) {
 			return nil, err
 		}
 		return &bm, nil
+	case *bcproto.Message_ChunkedBlockResponse:
+		bm := ChunkedBlockResponseMessage{
+			Height: msg.ChunkedBlockResponse.Height,
+			Index:  msg.ChunkedBlockResponse.Index,
+			Parts:  msg.ChunkedBlockResponse.Parts,
+			Chunk:  msg.ChunkedBlockResponse.Chunk,
+		}
+		if err := bm.ValidateBasic(); err != nil {
+			return nil, err
+		}
+		return &bm, nil
 	case *bcproto.Message_StatusRequest:
 		bm := StatusRequestMessage{Height: msg.StatusRequest.Height}
 		if err := bm.ValidateBasic(); err != nil {
@@ -96,12 +233,50 @@ func MsgFromProto(bcm bcproto.Message) (Message, error) {
 		}
 		return &bm, nil
 	case *bcproto.Message_StatusResponse:
-		bm := StatusRequestMessage{Height: msg.StatusResponse.Height}
+		bm := StatusResponseMessage{Height: msg.StatusResponse.Height, Base: msg.StatusResponse.Base}
+		if err := bm.ValidateBasic(); err != nil {
+			return nil, err
+		}
+		return &bm, nil
+	case *bcproto.Message_SnapshotRequest:
+		bm := SnapshotRequestMessage{}
+		if err := bm.ValidateBasic(); err != nil {
+			return nil, err
+		}
+		return &bm, nil
+	case *bcproto.Message_SnapshotResponse:
+		bm := SnapshotResponseMessage{
+			Height:      msg.SnapshotResponse.Height,
+			Format:      msg.SnapshotResponse.Format,
+			NumChunks:   msg.SnapshotResponse.NumChunks,
+			ChunkHashes: msg.SnapshotResponse.ChunkHashes,
+		}
+		if err := bm.ValidateBasic(); err != nil {
+			return nil, err
+		}
+		return &bm, nil
+	case *bcproto.Message_ChunkRequest:
+		bm := ChunkRequestMessage{
+			Height: msg.ChunkRequest.Height,
+			Format: msg.ChunkRequest.Format,
+			Index:  msg.ChunkRequest.Index,
+		}
+		if err := bm.ValidateBasic(); err != nil {
+			return nil, err
+		}
+		return &bm, nil
+	case *bcproto.Message_ChunkResponse:
+		bm := ChunkResponseMessage{
+			Height: msg.ChunkResponse.Height,
+			Format: msg.ChunkResponse.Format,
+			Index:  msg.ChunkResponse.Index,
+			Chunk:  msg.ChunkResponse.Chunk,
+		}
 		if err := bm.ValidateBasic(); err != nil {
 			return nil, err
 		}
 		return &bm, nil
 	default:
-		return nil, errors.New("evidence is not recognized")
+		return nil, fmt.Errorf("blockchain message %T is not recognized", msg)
 	}
-}
\ No newline at end of file
+}
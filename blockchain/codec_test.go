@@ -0,0 +1,100 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bcproto "github.com/tendermint/tendermint/proto/blockchain"
+	"github.com/tendermint/tendermint/types"
+)
+
+// TestMsgToProtoStatusRoundTrip guards against the StatusRequest/
+// StatusResponse mix-up that MsgToProto/MsgFromProto previously had: a
+// StatusRequestMessage must come back out as a StatusRequestMessage, not a
+// StatusResponseMessage, and vice-versa.
+func TestMsgToProtoStatusRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   Message
+	}{
+		{"status request", &StatusRequestMessage{Height: 10}},
+		{"status response", &StatusResponseMessage{Height: 10, Base: 1}},
+		{"block request", &BlockRequestMessage{Height: 10}},
+		{"no block response", &NoBlockResponseMessage{Height: 10, Reason: "pruned"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pb, err := MsgToProto(tc.in)
+			require.NoError(t, err)
+
+			out, err := MsgFromProto(*pb)
+			require.NoError(t, err)
+
+			assert.IsType(t, tc.in, out)
+			assert.Equal(t, tc.in, out)
+		})
+	}
+}
+
+// TestEnvelopeCompatibilityMatrix round-trips every message type through
+// both the legacy (v0, unwrapped) and current (v1, Envelope-wrapped) wire
+// formats, to make sure v1 readers can still understand v0 bytes.
+func TestEnvelopeCompatibilityMatrix(t *testing.T) {
+	messages := []Message{
+		&BlockRequestMessage{Height: 10},
+		&BlockResponseMessage{Block: &types.Block{Header: types.Header{Height: 10}}},
+		&NoBlockResponseMessage{Height: 10, Reason: "not available"},
+		&StatusRequestMessage{Height: 10},
+		&StatusResponseMessage{Height: 10, Base: 1},
+		&ChunkedBlockResponseMessage{Height: 10, Index: 0, Parts: 2, Chunk: []byte("chunk0")},
+		&SnapshotRequestMessage{},
+		&SnapshotResponseMessage{Height: 10, Format: 1, NumChunks: 2, ChunkHashes: [][]byte{[]byte("h0"), []byte("h1")}},
+		&ChunkRequestMessage{Height: 10, Format: 1, Index: 0},
+		&ChunkResponseMessage{Height: 10, Format: 1, Index: 0, Chunk: []byte("chunk0")},
+	}
+
+	for _, m := range messages {
+		pb, err := MsgToProto(m)
+		require.NoError(t, err)
+
+		for _, version := range []uint32{ProtocolVersionV0, ProtocolVersionV1} {
+			env := bcproto.Envelope{ProtocolVersion: version, Sum: pb.Sum}
+			bz, err := env.Marshal()
+			require.NoError(t, err)
+
+			out, err := DecodeMessage(bz)
+			require.NoError(t, err)
+			assert.Equal(t, m, out)
+		}
+	}
+}
+
+// TestDecodeMessageAcceptsLegacyBareMessage guards the actual backward
+// compatibility claim: a v0 peer sends a bare bcproto.Message with no
+// Envelope wrapper at all, and DecodeMessage must still understand it.
+func TestDecodeMessageAcceptsLegacyBareMessage(t *testing.T) {
+	pb, err := MsgToProto(&BlockRequestMessage{Height: 10})
+	require.NoError(t, err)
+
+	bz, err := pb.Marshal()
+	require.NoError(t, err)
+
+	out, err := DecodeMessage(bz)
+	require.NoError(t, err)
+	assert.Equal(t, &BlockRequestMessage{Height: 10}, out)
+}
+
+func TestDecodeMessageRejectsUnknownProtocolVersion(t *testing.T) {
+	pb, err := MsgToProto(&BlockRequestMessage{Height: 10})
+	require.NoError(t, err)
+
+	env := bcproto.Envelope{ProtocolVersion: 99, Sum: pb.Sum}
+	bz, err := env.Marshal()
+	require.NoError(t, err)
+
+	_, err = DecodeMessage(bz)
+	assert.Error(t, err)
+}
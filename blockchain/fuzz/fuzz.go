@@ -0,0 +1,28 @@
+// +build gofuzz
+
+package fuzz
+
+import (
+	"github.com/tendermint/tendermint/blockchain"
+)
+
+// Fuzz exercises blockchain.DecodeMessage with arbitrary input, run via
+// `go-fuzz-build && go-fuzz -bin blockchain-fuzz.zip -workdir blockchain/fuzz`.
+// Seed corpus lives in blockchain/fuzz/corpus.
+func Fuzz(data []byte) int {
+	msg, err := blockchain.DecodeMessage(data)
+	if err != nil {
+		return 0
+	}
+	if msg == nil {
+		panic("DecodeMessage returned nil message with nil error")
+	}
+
+	// Round-trip: a message we just decoded should always re-encode
+	// without error.
+	if _, err := blockchain.EncodeMessage(msg); err != nil {
+		panic(err)
+	}
+
+	return 1
+}
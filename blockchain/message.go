@@ -0,0 +1,233 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// Message is a generic message for this reactor.
+type Message interface {
+	ValidateBasic() error
+}
+
+// BlockRequestMessage requests a block for a specific height.
+type BlockRequestMessage struct {
+	Height int64
+}
+
+// ValidateBasic performs basic validation.
+func (m *BlockRequestMessage) ValidateBasic() error {
+	if m.Height < 0 {
+		return fmt.Errorf("negative Height %d", m.Height)
+	}
+	return nil
+}
+
+func (m *BlockRequestMessage) String() string {
+	return fmt.Sprintf("[BlockRequestMessage %v]", m.Height)
+}
+
+// BlockResponseMessage contains a block at the requested height.
+type BlockResponseMessage struct {
+	Block *types.Block
+}
+
+// ValidateBasic performs basic validation.
+func (m *BlockResponseMessage) ValidateBasic() error {
+	if m.Block == nil {
+		return fmt.Errorf("block response message has nil block")
+	}
+	return m.Block.ValidateBasic()
+}
+
+func (m *BlockResponseMessage) String() string {
+	return fmt.Sprintf("[BlockResponseMessage %v]", m.Block.Height)
+}
+
+// NoBlockResponseMessage is sent when the requested block at Height was not
+// found. Reason distinguishes why: "not available" (never had it), "pruned"
+// (had it, dropped it), or the zero value for the legacy, unqualified case.
+type NoBlockResponseMessage struct {
+	Height int64
+	Reason string
+}
+
+// ValidateBasic performs basic validation.
+func (m *NoBlockResponseMessage) ValidateBasic() error {
+	if m.Height < 0 {
+		return fmt.Errorf("negative Height %d", m.Height)
+	}
+	return nil
+}
+
+func (m *NoBlockResponseMessage) String() string {
+	return fmt.Sprintf("[NoBlockResponseMessage %v, reason %q]", m.Height, m.Reason)
+}
+
+// StatusRequestMessage requests the sender's current height.
+type StatusRequestMessage struct {
+	Height int64
+}
+
+// ValidateBasic performs basic validation.
+func (m *StatusRequestMessage) ValidateBasic() error {
+	if m.Height < 0 {
+		return fmt.Errorf("negative Height %d", m.Height)
+	}
+	return nil
+}
+
+func (m *StatusRequestMessage) String() string {
+	return fmt.Sprintf("[StatusRequestMessage %v]", m.Height)
+}
+
+// StatusResponseMessage carries the sender's current height. Base is the
+// lowest height the sender still has a block for, which lets a state-syncing
+// peer tell whether it can fast-sync from this peer or needs a snapshot
+// first.
+type StatusResponseMessage struct {
+	Height int64
+	Base   int64
+}
+
+// ValidateBasic performs basic validation.
+func (m *StatusResponseMessage) ValidateBasic() error {
+	if m.Height < 0 {
+		return fmt.Errorf("negative Height %d", m.Height)
+	}
+	if m.Base < 0 {
+		return fmt.Errorf("negative Base %d", m.Base)
+	}
+	if m.Base > m.Height {
+		return fmt.Errorf("base %d cannot be greater than height %d", m.Base, m.Height)
+	}
+	return nil
+}
+
+func (m *StatusResponseMessage) String() string {
+	return fmt.Sprintf("[StatusResponseMessage %v:%v]", m.Base, m.Height)
+}
+
+// ChunkedBlockResponseMessage carries one part of a block at Height that
+// was too large to fit in a single BlockResponseMessage. Parts is the total
+// number of chunks the block was split into and Index identifies this one;
+// the receiver reassembles the block once it has chunks 0..Parts-1.
+type ChunkedBlockResponseMessage struct {
+	Height int64
+	Index  int32
+	Parts  int32
+	Chunk  []byte
+}
+
+// ValidateBasic performs basic validation.
+func (m *ChunkedBlockResponseMessage) ValidateBasic() error {
+	if m.Height < 0 {
+		return fmt.Errorf("negative Height %d", m.Height)
+	}
+	if m.Parts <= 0 {
+		return fmt.Errorf("non-positive Parts %d", m.Parts)
+	}
+	if m.Index < 0 || m.Index >= m.Parts {
+		return fmt.Errorf("index %d out of range [0,%d)", m.Index, m.Parts)
+	}
+	if len(m.Chunk) == 0 {
+		return fmt.Errorf("chunk is empty")
+	}
+	return nil
+}
+
+func (m *ChunkedBlockResponseMessage) String() string {
+	return fmt.Sprintf("[ChunkedBlockResponseMessage %v %v/%v]", m.Height, m.Index+1, m.Parts)
+}
+
+// SnapshotRequestMessage asks the peer for the manifest of a state-sync
+// snapshot it advertised in its status response.
+type SnapshotRequestMessage struct{}
+
+// ValidateBasic performs basic validation.
+func (m *SnapshotRequestMessage) ValidateBasic() error {
+	return nil
+}
+
+func (m *SnapshotRequestMessage) String() string {
+	return "[SnapshotRequestMessage]"
+}
+
+// SnapshotResponseMessage carries the manifest of a snapshot: the height
+// and application format it was taken at, how many chunks it is split
+// into, and the hash of each chunk so the receiver can verify chunks as
+// they arrive without waiting for the whole snapshot.
+type SnapshotResponseMessage struct {
+	Height      int64
+	Format      uint32
+	NumChunks   int32
+	ChunkHashes [][]byte
+}
+
+// ValidateBasic performs basic validation.
+func (m *SnapshotResponseMessage) ValidateBasic() error {
+	if m.Height < 0 {
+		return fmt.Errorf("negative Height %d", m.Height)
+	}
+	if m.NumChunks <= 0 {
+		return fmt.Errorf("non-positive NumChunks %d", m.NumChunks)
+	}
+	if len(m.ChunkHashes) != int(m.NumChunks) {
+		return fmt.Errorf("expected %d chunk hashes, got %d", m.NumChunks, len(m.ChunkHashes))
+	}
+	return nil
+}
+
+func (m *SnapshotResponseMessage) String() string {
+	return fmt.Sprintf("[SnapshotResponseMessage %v, format %v, %v chunks]", m.Height, m.Format, m.NumChunks)
+}
+
+// ChunkRequestMessage requests one chunk of the snapshot currently being
+// fetched.
+type ChunkRequestMessage struct {
+	Height int64
+	Format uint32
+	Index  int32
+}
+
+// ValidateBasic performs basic validation.
+func (m *ChunkRequestMessage) ValidateBasic() error {
+	if m.Height < 0 {
+		return fmt.Errorf("negative Height %d", m.Height)
+	}
+	if m.Index < 0 {
+		return fmt.Errorf("negative Index %d", m.Index)
+	}
+	return nil
+}
+
+func (m *ChunkRequestMessage) String() string {
+	return fmt.Sprintf("[ChunkRequestMessage %v/%v #%v]", m.Height, m.Format, m.Index)
+}
+
+// ChunkResponseMessage carries one chunk of a snapshot.
+type ChunkResponseMessage struct {
+	Height int64
+	Format uint32
+	Index  int32
+	Chunk  []byte
+}
+
+// ValidateBasic performs basic validation.
+func (m *ChunkResponseMessage) ValidateBasic() error {
+	if m.Height < 0 {
+		return fmt.Errorf("negative Height %d", m.Height)
+	}
+	if m.Index < 0 {
+		return fmt.Errorf("negative Index %d", m.Index)
+	}
+	if len(m.Chunk) == 0 {
+		return fmt.Errorf("chunk is empty")
+	}
+	return nil
+}
+
+func (m *ChunkResponseMessage) String() string {
+	return fmt.Sprintf("[ChunkResponseMessage %v/%v #%v, %d bytes]", m.Height, m.Format, m.Index, len(m.Chunk))
+}
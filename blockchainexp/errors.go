@@ -0,0 +1,28 @@
+package blockchainexp
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	errNilPeerForBlockRequest = errors.New("nil peer for block request")
+	errSendQueueFull          = errors.New("block request not sent, send-queue is full")
+	errPeerTooShort           = errors.New("peer height too low to announce")
+	errPeerLowersItsHeight    = errors.New("peer cannot lower its height")
+	errBadDataFromPeer        = errors.New("bad data from peer")
+	errMissingBlock           = errors.New("missing block")
+	errNoPeerResponse         = errors.New("peer did not respond")
+)
+
+// errSlowPeer indicates that a peer's recv rate has fallen below
+// minRecvRate for long enough that it is no longer worth keeping around
+// during fast-sync.
+type errSlowPeer struct {
+	recvRate int64
+}
+
+func (e errSlowPeer) Error() string {
+	return fmt.Sprintf("peer is too slow, recv rate %d bytes/s is below minimum %d bytes/s",
+		e.recvRate, minRecvRate)
+}
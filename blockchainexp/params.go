@@ -0,0 +1,70 @@
+package blockchainexp
+
+import "time"
+
+const (
+	// maxRequestsPerPeer bounds how many outstanding block requests the
+	// pool will keep in flight to any single peer.
+	maxRequestsPerPeer = 20
+
+	// maxNumRequests is the upper bound on the number of blocks the pool
+	// will keep queued (requested or received but not yet processed) at
+	// once, across all peers.
+	maxNumRequests = 64
+
+	// maxTotalRequesters bounds the total number of in-flight block
+	// requests the pool will have outstanding across all peers combined.
+	maxTotalRequesters = 100
+
+	// maxDiffBetweenCurrentAndReceivedBlockHeight caps how far above
+	// pool.Height the pool is willing to queue outstanding requests. This
+	// keeps a single stalled tip peer from causing the pool to queue an
+	// unbounded number of heights.
+	maxDiffBetweenCurrentAndReceivedBlockHeight = 100
+
+	// minRecvRate is the minimum tolerated bytes/sec a peer must sustain,
+	// averaged over its flowrate.Monitor window, before it is removed as a
+	// slow peer.
+	minRecvRate = int64(128 * 1024) // 128 KB/s
+
+	// Weights for the peer scoring function used by sendRequest to rank
+	// candidate peers for a given height:
+	//
+	//   score = recvRate - alphaPendingRequests*pending - betaTimeouts*timeouts + gammaHeightLead*(peerHeight-height)
+	//
+	// alphaPendingRequests penalizes peers that already have a lot of our
+	// in-flight requests, so load spreads out instead of piling onto
+	// whichever peer happened to respond first.
+	alphaPendingRequests = 4096.0
+	// betaTimeouts penalizes peers with a history of timed-out requests
+	// more heavily than raw pending count, since a timeout is a stronger
+	// signal of trouble than a request that simply hasn't come back yet.
+	betaTimeouts = 65536.0
+	// gammaHeightLead rewards peers that are further ahead of the
+	// requested height, since they're less likely to run out of blocks to
+	// serve while fast-sync is catching up.
+	gammaHeightLead = 1024.0
+
+	// ewmaAlpha is the smoothing factor for the peer recv-rate EWMA: closer
+	// to 1 reacts faster to recent samples, closer to 0 smooths more.
+	ewmaAlpha = 0.3
+)
+
+var (
+	// peerTimeout is how long the pool waits for a response to a single
+	// block request before giving up on the assigned peer and
+	// rescheduling the request elsewhere. It is a var, not a const, so
+	// tests can shrink it.
+	peerTimeout = 15 * time.Second
+
+	// requestInterval is how often MakeNextRequests is invoked by the
+	// reactor's scheduling ticker. It is a var, not a const, so tests can
+	// shrink it.
+	requestInterval = 10 * time.Millisecond
+
+	// peerConnWait is the grace period, measured from pool start, during
+	// which MakeNextRequests is a no-op. It gives the reactor time to
+	// discover and register peers before the pool starts handing out
+	// requests to whatever happens to have connected first.
+	peerConnWait = 3 * time.Second
+)
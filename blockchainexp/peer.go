@@ -0,0 +1,150 @@
+package blockchainexp
+
+import (
+	"time"
+
+	"github.com/tendermint/tendermint/libs/flowrate"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/types"
+)
+
+// peerErrorFunc is called when the pool detects a peer misbehaving so the
+// reactor can penalize it (e.g. via the switch's peer behaviour reporter).
+type peerErrorFunc func(err error, peerID p2p.ID)
+
+// bpPeer tracks the blocks requested from, and the receive rate of, a
+// single peer during fast-sync.
+type bpPeer struct {
+	logger log.Logger
+
+	ID     p2p.ID
+	Height int64
+
+	NumPendingBlockRequests int
+	blocks                  map[int64]*types.Block
+
+	recvMonitor *flowrate.Monitor
+
+	// Snapshot advertised by this peer for state-sync bootstrap, if any.
+	hasSnapshot    bool
+	snapshotHeight int64
+	snapshotFormat uint32
+
+	// Rolling stats used by the pool's scoring function in sendRequest, see
+	// PeerStats.
+	ewmaRecvRate     float64
+	timeoutCount     int
+	lastServedHeight int64
+
+	onErr peerErrorFunc
+}
+
+// PeerStats is a snapshot of a peer's rolling performance stats, used by
+// the pool to score candidate peers and exposed for tests and metrics.
+type PeerStats struct {
+	ID               p2p.ID
+	EWMARecvRate     float64
+	CurRecvRate      int64
+	NumPending       int
+	TimeoutCount     int
+	LastServedHeight int64
+}
+
+// Stats returns a snapshot of the peer's current rolling stats.
+func (peer *bpPeer) Stats() PeerStats {
+	return PeerStats{
+		ID:               peer.ID,
+		EWMARecvRate:     peer.ewmaRecvRate,
+		CurRecvRate:      peer.recvMonitor.Status().CurRate,
+		NumPending:       peer.NumPendingBlockRequests,
+		TimeoutCount:     peer.timeoutCount,
+		LastServedHeight: peer.lastServedHeight,
+	}
+}
+
+// recordTimeout bumps the peer's timeout count, used to penalize it in the
+// pool's peer-scoring function.
+func (peer *bpPeer) recordTimeout() {
+	peer.timeoutCount++
+}
+
+// NewBPPeer creates a new peer.
+func NewBPPeer(peerID p2p.ID, height int64, onErr peerErrorFunc, logger log.Logger) *bpPeer {
+	peer := &bpPeer{
+		ID:          peerID,
+		Height:      height,
+		blocks:      make(map[int64]*types.Block),
+		recvMonitor: flowrate.New(time.Second),
+		onErr:       onErr,
+		logger:      log.NewNopLogger(),
+	}
+	if logger != nil {
+		peer.logger = logger
+	}
+	return peer
+}
+
+// SetLogger sets the logger of the peer.
+func (peer *bpPeer) SetLogger(l log.Logger) {
+	peer.logger = l
+}
+
+// RequestSent records that a block request for height was sent to this peer.
+func (peer *bpPeer) RequestSent(height int64) {
+	peer.NumPendingBlockRequests++
+}
+
+// AddBlock records a block received from the peer, crediting its flowrate
+// monitor with blockSize bytes.
+func (peer *bpPeer) AddBlock(block *types.Block, blockSize int) error {
+	if _, ok := peer.blocks[block.Height]; ok {
+		return errBadDataFromPeer
+	}
+	peer.blocks[block.Height] = block
+	if peer.NumPendingBlockRequests > 0 {
+		peer.NumPendingBlockRequests--
+	}
+	curRate := peer.recvMonitor.Update(blockSize)
+	peer.ewmaRecvRate = ewmaAlpha*float64(curRate) + (1-ewmaAlpha)*peer.ewmaRecvRate
+	peer.lastServedHeight = block.Height
+	return nil
+}
+
+// BlockAtHeight returns the block received from the peer at height, or
+// errMissingBlock if it hasn't arrived yet.
+func (peer *bpPeer) BlockAtHeight(height int64) (*types.Block, error) {
+	block, ok := peer.blocks[height]
+	if !ok {
+		return nil, errMissingBlock
+	}
+	return block, nil
+}
+
+// RemoveBlock discards the block at height, if any, typically after it has
+// been processed or the request needs to be retried elsewhere.
+func (peer *bpPeer) RemoveBlock(height int64) {
+	delete(peer.blocks, height)
+}
+
+// CheckRate returns errSlowPeer if the peer's recv rate has fallen below
+// minRecvRate. A peer that hasn't received any bytes at all yet is given
+// the benefit of the doubt rather than judged on a rate computed from zero
+// samples; one that did receive data and has since gone silent has its
+// rate correctly trimmed to 0 by the monitor's rolling window, and that
+// must count as slow, not be exempted from the check.
+func (peer *bpPeer) CheckRate() error {
+	status := peer.recvMonitor.Status()
+	if status.Bytes == 0 {
+		return nil
+	}
+	if status.CurRate < minRecvRate {
+		return errSlowPeer{recvRate: status.CurRate}
+	}
+	return nil
+}
+
+// Cleanup releases the peer's buffered blocks.
+func (peer *bpPeer) Cleanup() {
+	peer.blocks = make(map[int64]*types.Block)
+}
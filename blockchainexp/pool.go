@@ -2,6 +2,7 @@ package blockchainexp
 
 import (
 	"sort"
+	"time"
 
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/p2p"
@@ -14,6 +15,8 @@ type blockPool struct {
 	peers map[p2p.ID]*bpPeer
 	// Set of block heights and the corresponding peers from where a block response is expected or has been received.
 	blocks map[int64]p2p.ID
+	// Height at which each outstanding request was sent, keyed by height, used to detect and retry timed out requests.
+	requestSentAt map[int64]time.Time
 
 	plannedRequests   map[int64]struct{} // list of blocks to be assigned peers for blockRequest
 	nextRequestHeight int64              // next height to be added to plannedRequests
@@ -21,18 +24,35 @@ type blockPool struct {
 	Height        int64 // height of next block to execute
 	MaxPeerHeight int64 // maximum height of all peers
 	toBcR         bcReactor
+
+	startTime  time.Time // when the pool was created, used to gate requests during peerConnWait
+	initHeight int64     // pool.Height at creation time, used to compute BlocksPerSecond
+
+	// SnapshotMode is true while the pool is fetching and verifying a
+	// state-sync snapshot instead of requesting blocks one by one.
+	SnapshotMode bool
+	snapshot     *snapshotState
+	// chunkRequestSentAt records when each outstanding snapshot chunk
+	// request was sent, keyed by chunk index, mirroring requestSentAt so
+	// retryTimedOutChunkRequests can detect a peer that accepted a chunk
+	// request and never replied.
+	chunkRequestSentAt map[int32]time.Time
 }
 
 // NewBlockPool creates a new blockPool.
 func NewBlockPool(height int64, toBcR bcReactor) *blockPool {
 	return &blockPool{
-		peers:             make(map[p2p.ID]*bpPeer),
-		MaxPeerHeight:     0,
-		blocks:            make(map[int64]p2p.ID),
-		plannedRequests:   make(map[int64]struct{}),
-		nextRequestHeight: height,
-		Height:            height,
-		toBcR:             toBcR,
+		peers:              make(map[p2p.ID]*bpPeer),
+		MaxPeerHeight:      0,
+		blocks:             make(map[int64]p2p.ID),
+		requestSentAt:      make(map[int64]time.Time),
+		plannedRequests:    make(map[int64]struct{}),
+		nextRequestHeight:  height,
+		Height:             height,
+		toBcR:              toBcR,
+		startTime:          time.Now(),
+		initHeight:         height,
+		chunkRequestSentAt: make(map[int32]time.Time),
 	}
 }
 
@@ -50,7 +70,16 @@ func (pool *blockPool) rescheduleRequest(peerID p2p.ID, height int64) {
 	pool.logger.Info("reschedule requests made to peer for height ", "peerID", peerID, "height", height)
 	pool.plannedRequests[height] = struct{}{}
 	delete(pool.blocks, height)
-	pool.peers[peerID].RemoveBlock(height)
+	delete(pool.requestSentAt, height)
+	peer := pool.peers[peerID]
+	// The request is no longer outstanding, so it must stop counting
+	// against the peer's pending-request budget, or a peer that
+	// accumulates maxRequestsPerPeer timeouts over its lifetime would be
+	// excluded from candidatesForRequest forever, even after it recovers.
+	if peer.NumPendingBlockRequests > 0 {
+		peer.NumPendingBlockRequests--
+	}
+	peer.RemoveBlock(height)
 }
 
 // Updates the pool's max height. If no peers are left MaxPeerHeight is set to 0.
@@ -98,6 +127,139 @@ func (pool *blockPool) UpdatePeer(peerID p2p.ID, height int64) error {
 	return nil
 }
 
+// UpdatePeerSnapshot records that peerID advertised a snapshot at height
+// with the given application format, making it a candidate for chunk
+// requests once the pool enters SnapshotMode via StartSnapshotMode.
+func (pool *blockPool) UpdatePeerSnapshot(peerID p2p.ID, height int64, format uint32) error {
+	peer := pool.peers[peerID]
+	if peer == nil {
+		return errBadDataFromPeer
+	}
+	peer.hasSnapshot = true
+	peer.snapshotHeight = height
+	peer.snapshotFormat = format
+	return nil
+}
+
+// StartSnapshotMode switches the pool into SnapshotMode: instead of
+// requesting blocks, it fetches and verifies the chunks described by
+// manifest in parallel across peers that advertised it, then resumes
+// normal block-by-block fast-sync from manifest.Height+1.
+func (pool *blockPool) StartSnapshotMode(manifest snapshotManifest) {
+	pool.SnapshotMode = true
+	pool.snapshot = newSnapshotState(manifest)
+}
+
+// MakeNextChunkRequests requests up to maxNumRequests snapshot chunks that
+// have not yet been requested or received, spreading them across whichever
+// peers advertised the snapshot and still have request capacity. It is a
+// no-op outside of SnapshotMode.
+func (pool *blockPool) MakeNextChunkRequests(maxNumRequests int) {
+	if pool.snapshot == nil {
+		return
+	}
+	pool.retryTimedOutChunkRequests()
+	for _, chunkIndex := range pool.snapshot.nextChunksNeeded(maxNumRequests) {
+		pool.sendChunkRequest(chunkIndex)
+	}
+}
+
+// retryTimedOutChunkRequests frees any snapshot chunk request that has
+// been outstanding for longer than peerTimeout without a response, the
+// chunk-request equivalent of retryTimedOutRequests. Without it, a peer
+// that accepts a chunk request and simply never replies leaves that chunk
+// stuck in snapshot.pending forever, since nextChunksNeeded only re-offers
+// a chunk once it is no longer pending.
+func (pool *blockPool) retryTimedOutChunkRequests() {
+	for chunkIndex, sentAt := range pool.chunkRequestSentAt {
+		if time.Since(sentAt) < peerTimeout {
+			continue
+		}
+		peerID, ok := pool.snapshot.pending[chunkIndex]
+		if !ok {
+			delete(pool.chunkRequestSentAt, chunkIndex)
+			continue
+		}
+		pool.logger.Info("retrying timed out chunk request", "peerID", peerID, "chunk", chunkIndex)
+		if peer, ok := pool.peers[peerID]; ok {
+			peer.recordTimeout()
+			if peer.NumPendingBlockRequests > 0 {
+				peer.NumPendingBlockRequests--
+			}
+		}
+		delete(pool.snapshot.pending, chunkIndex)
+		delete(pool.chunkRequestSentAt, chunkIndex)
+	}
+}
+
+// sendChunkRequest assigns chunkIndex to the best-scoring peer with an
+// advertised snapshot and spare request capacity, using the same
+// recv-rate/pending/timeout scoring as sendRequest instead of map order.
+func (pool *blockPool) sendChunkRequest(chunkIndex int32) bool {
+	candidates := pool.rankPeers(
+		func(peer *bpPeer) bool {
+			return peer.hasSnapshot && peer.NumPendingBlockRequests < maxRequestsPerPeer
+		},
+		chunkPeerScore,
+	)
+
+	for _, peer := range candidates {
+		if err := pool.toBcR.sendChunkRequest(peer.ID, chunkIndex); err != nil {
+			pool.logger.Error("failed to send chunk request", "peer", peer.ID, "chunk", chunkIndex, "err", err)
+			continue
+		}
+
+		pool.snapshot.pending[chunkIndex] = peer.ID
+		pool.chunkRequestSentAt[chunkIndex] = time.Now()
+		peer.RequestSent(int64(chunkIndex))
+		return true
+	}
+	pool.logger.Error("could not find peer to send chunk request", "chunk", chunkIndex)
+	return false
+}
+
+// AddChunk stores a snapshot chunk received from peerID once its hash has
+// been checked against the manifest. A chunk that fails verification is
+// dropped, its peer is disconnected as a bad actor, and the chunk index is
+// freed up for another sendChunkRequest pass. Once every chunk has arrived
+// and verified, the pool leaves SnapshotMode and resumes normal block
+// fast-sync from snapshot.Height+1.
+func (pool *blockPool) AddChunk(peerID p2p.ID, chunkIndex int32, chunk []byte) error {
+	if pool.snapshot == nil {
+		return errBadDataFromPeer
+	}
+	if wantPeerID, ok := pool.snapshot.pending[chunkIndex]; ok && wantPeerID != peerID {
+		return errBadDataFromPeer
+	}
+	if !pool.snapshot.manifest.verifyChunk(chunkIndex, chunk) {
+		pool.logger.Error("chunk failed verification against manifest, rescheduling",
+			"peer", peerID, "chunk", chunkIndex)
+		delete(pool.snapshot.pending, chunkIndex)
+		delete(pool.chunkRequestSentAt, chunkIndex)
+		pool.RemovePeer(peerID, errBadDataFromPeer)
+		return errBadDataFromPeer
+	}
+
+	pool.snapshot.chunks[chunkIndex] = chunk
+	delete(pool.snapshot.pending, chunkIndex)
+	delete(pool.chunkRequestSentAt, chunkIndex)
+	if peer := pool.peers[peerID]; peer != nil && peer.NumPendingBlockRequests > 0 {
+		peer.NumPendingBlockRequests--
+	}
+
+	if pool.snapshot.done() {
+		newHeight := pool.snapshot.manifest.Height + 1
+		pool.logger.Info("snapshot complete, resuming block fast-sync", "height", newHeight)
+		pool.Height = newHeight
+		pool.nextRequestHeight = newHeight
+		pool.initHeight = newHeight
+		pool.SnapshotMode = false
+		pool.snapshot = nil
+		pool.chunkRequestSentAt = make(map[int32]time.Time)
+	}
+	return nil
+}
+
 // Cleans and deletes the peer. Recomputes the max peer height.
 func (pool *blockPool) deletePeer(peer *bpPeer) {
 	if peer == nil {
@@ -125,6 +287,19 @@ func (pool *blockPool) RemovePeer(peerID p2p.ID, err error) {
 		pool.rescheduleRequest(peerID, h)
 	}
 
+	// Likewise, free up any snapshot chunks pending against this peer, or
+	// SnapshotMode would wait forever for chunks that will never arrive:
+	// nextChunksNeeded only re-offers a chunk once it's no longer in
+	// snapshot.pending.
+	if pool.snapshot != nil {
+		for chunkIndex, pendingPeerID := range pool.snapshot.pending {
+			if pendingPeerID == peerID {
+				delete(pool.snapshot.pending, chunkIndex)
+				delete(pool.chunkRequestSentAt, chunkIndex)
+			}
+		}
+	}
+
 	oldMaxPeerHeight := pool.MaxPeerHeight
 	// Delete the peer. This operation may result in the pool's MaxPeerHeight being lowered.
 	pool.deletePeer(peer)
@@ -164,7 +339,31 @@ func (pool *blockPool) removeBadPeers() {
 }
 
 // MakeNextRequests creates more requests if the block pool is running low.
+// During the initial peerConnWait grace period after the pool is created it
+// is a no-op, giving the reactor a chance to discover peers before the pool
+// starts handing out requests to whichever peer happened to connect first.
 func (pool *blockPool) MakeNextRequests(maxNumRequests int) {
+	if time.Since(pool.startTime) < peerConnWait {
+		return
+	}
+	if pool.SnapshotMode {
+		// Block-height requests and snapshot-chunk requests are mutually
+		// exclusive: while SnapshotMode is fetching and verifying chunks,
+		// requesting blocks from the pre-snapshot height would just be
+		// downloaded and immediately discarded once pool.Height jumps to
+		// snapshot.Height+1.
+		return
+	}
+
+	pool.retryTimedOutRequests()
+
+	if len(pool.blocks) >= maxTotalRequesters {
+		return
+	}
+	if maxNumRequests > maxTotalRequesters-len(pool.blocks) {
+		maxNumRequests = maxTotalRequesters - len(pool.blocks)
+	}
+
 	heights := pool.makeRequestBatch(maxNumRequests)
 	pool.logger.Info("makeNextRequests will make following requests", "number", len(heights), "heights", heights)
 
@@ -179,6 +378,28 @@ func (pool *blockPool) MakeNextRequests(maxNumRequests int) {
 	}
 }
 
+// retryTimedOutRequests reschedules requests that have been outstanding for
+// longer than peerTimeout, freeing the slow peer's slot and giving the
+// height to a different peer on the next sendRequest pass.
+func (pool *blockPool) retryTimedOutRequests() {
+	for height, sentAt := range pool.requestSentAt {
+		if time.Since(sentAt) < peerTimeout {
+			continue
+		}
+		peerID, ok := pool.blocks[height]
+		if !ok {
+			delete(pool.requestSentAt, height)
+			continue
+		}
+		pool.logger.Info("retrying timed out request", "peerID", peerID, "height", height)
+		if peer, ok := pool.peers[peerID]; ok {
+			peer.recordTimeout()
+		}
+		pool.rescheduleRequest(peerID, height)
+		delete(pool.requestSentAt, height)
+	}
+}
+
 // Makes a batch of requests sorted by height such that the block pool has up to maxNumRequests entries.
 func (pool *blockPool) makeRequestBatch(maxNumRequests int) []int {
 	pool.removeBadPeers()
@@ -192,6 +413,12 @@ func (pool *blockPool) makeRequestBatch(maxNumRequests int) []int {
 		if pool.nextRequestHeight > pool.MaxPeerHeight {
 			break
 		}
+		// Don't queue requests for heights too far above pool.Height: if the
+		// tallest peer stalls, this keeps the pool from requesting an
+		// unbounded number of blocks it has no room to process yet.
+		if pool.nextRequestHeight-pool.Height > maxDiffBetweenCurrentAndReceivedBlockHeight {
+			break
+		}
 		pool.plannedRequests[pool.nextRequestHeight] = struct{}{}
 		pool.nextRequestHeight++
 	}
@@ -204,15 +431,64 @@ func (pool *blockPool) makeRequestBatch(maxNumRequests int) []int {
 	return heights
 }
 
-func (pool *blockPool) sendRequest(height int64) bool {
+// peerScore ranks peer as a candidate to serve height: higher is better.
+// It favors peers with a high recent recv rate and a height comfortably
+// ahead of what's being requested, and penalizes peers that already have a
+// lot of our requests outstanding or a history of timing out.
+func peerScore(peer *bpPeer, height int64) float64 {
+	return peer.ewmaRecvRate -
+		alphaPendingRequests*float64(peer.NumPendingBlockRequests) -
+		betaTimeouts*float64(peer.timeoutCount) +
+		gammaHeightLead*float64(peer.Height-height)
+}
+
+// chunkPeerScore ranks peer as a candidate to serve a snapshot chunk
+// request: the same recv-rate/pending/timeout trade-off as peerScore,
+// minus the height-lead term, since chunk requests aren't addressed by
+// block height.
+func chunkPeerScore(peer *bpPeer) float64 {
+	return peer.ewmaRecvRate -
+		alphaPendingRequests*float64(peer.NumPendingBlockRequests) -
+		betaTimeouts*float64(peer.timeoutCount)
+}
+
+// rankPeers returns the peers in pool.peers for which eligible is true,
+// ordered best-to-worst by score. Ties are broken by peer ID so the
+// ordering is deterministic, which matters for tests. It backs both
+// candidatesForRequest and sendChunkRequest so block and chunk requests
+// are scheduled with the same peer-scoring logic instead of one of them
+// reverting to map order.
+func (pool *blockPool) rankPeers(eligible func(*bpPeer) bool, score func(*bpPeer) float64) []*bpPeer {
+	candidates := make([]*bpPeer, 0, len(pool.peers))
 	for _, peer := range pool.peers {
-		if peer.NumPendingBlockRequests >= maxRequestsPerPeer {
-			continue
+		if eligible(peer) {
+			candidates = append(candidates, peer)
 		}
-		if peer.Height < height {
-			continue
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		si, sj := score(candidates[i]), score(candidates[j])
+		if si != sj {
+			return si > sj
 		}
+		return candidates[i].ID < candidates[j].ID
+	})
+	return candidates
+}
 
+// candidatesForRequest returns the peers able to serve height (tall enough,
+// with spare request capacity), ordered best-to-worst by peerScore.
+func (pool *blockPool) candidatesForRequest(height int64) []*bpPeer {
+	return pool.rankPeers(
+		func(peer *bpPeer) bool {
+			return peer.NumPendingBlockRequests < maxRequestsPerPeer && peer.Height >= height
+		},
+		func(peer *bpPeer) float64 { return peerScore(peer, height) },
+	)
+}
+
+func (pool *blockPool) sendRequest(height int64) bool {
+	for _, peer := range pool.candidatesForRequest(height) {
 		err := pool.toBcR.sendBlockRequest(peer.ID, height)
 		if err == errNilPeerForBlockRequest {
 			// Switch does not have this peer, remove it and continue to look for another peer.
@@ -226,9 +502,10 @@ func (pool *blockPool) sendRequest(height int64) bool {
 			continue
 		}
 
-		pool.logger.Info("assigned request to peer", "peer", peer.ID, "height", height)
+		pool.logger.Info("assigned request to peer", "peer", peer.ID, "height", height, "score", peerScore(peer, height))
 
 		pool.blocks[height] = peer.ID
+		pool.requestSentAt[height] = time.Now()
 		peer.RequestSent(height)
 
 		return true
@@ -237,6 +514,16 @@ func (pool *blockPool) sendRequest(height int64) bool {
 	return false
 }
 
+// PeerStats returns a snapshot of peerID's rolling performance stats, or
+// false if the peer is not known to the pool.
+func (pool *blockPool) PeerStats(peerID p2p.ID) (PeerStats, bool) {
+	peer, ok := pool.peers[peerID]
+	if !ok {
+		return PeerStats{}, false
+	}
+	return peer.Stats(), true
+}
+
 // Validates that the block comes from the peer it was expected from and stores it in the 'blocks' map.
 func (pool *blockPool) AddBlock(peerID p2p.ID, block *types.Block, blockSize int) error {
 	peer, ok := pool.peers[peerID]
@@ -250,7 +537,14 @@ func (pool *blockPool) AddBlock(peerID p2p.ID, block *types.Block, blockSize int
 		return errBadDataFromPeer
 	}
 
-	return peer.AddBlock(block, blockSize)
+	if err := peer.AddBlock(block, blockSize); err != nil {
+		return err
+	}
+	// The block arrived successfully, so it is no longer outstanding: clear
+	// its timer or retryTimedOutRequests will eventually mistake this
+	// already-delivered block for a timed-out request and discard it.
+	delete(pool.requestSentAt, block.Height)
+	return nil
 }
 
 type blockData struct {
@@ -307,6 +601,7 @@ func (pool *blockPool) ProcessedCurrentHeightBlock() {
 		pool.peers[peerID].RemoveBlock(pool.Height)
 	}
 	delete(pool.blocks, pool.Height)
+	delete(pool.requestSentAt, pool.Height)
 	pool.logger.Debug("removed block at height", "height", pool.Height)
 	pool.Height++
 	pool.removeShortPeers()
@@ -353,4 +648,30 @@ func (pool *blockPool) NumPeers() int {
 
 func (pool *blockPool) NeedsBlocks() bool {
 	return len(pool.blocks) < maxNumRequests
+}
+
+// NumPendingRequests returns the number of block requests currently
+// outstanding, i.e. sent to a peer but not yet fulfilled.
+func (pool *blockPool) NumPendingRequests() int {
+	return len(pool.blocks)
+}
+
+// BlocksPerSecond returns the number of blocks processed per second since
+// the pool was created.
+func (pool *blockPool) BlocksPerSecond() float64 {
+	secs := time.Since(pool.startTime).Seconds()
+	if secs < 1 {
+		secs = 1
+	}
+	return float64(pool.Height-pool.initHeight) / secs
+}
+
+// BytesPerSecond returns the aggregate receive rate, in bytes per second,
+// across all peers.
+func (pool *blockPool) BytesPerSecond() int64 {
+	var total int64
+	for _, peer := range pool.peers {
+		total += peer.recvMonitor.Status().CurRate
+	}
+	return total
 }
\ No newline at end of file
@@ -0,0 +1,134 @@
+package blockchainexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/types"
+)
+
+// fakeBcReactor is a no-op bcReactor used to drive blockPool in isolation,
+// without a real p2p.Switch.
+type fakeBcReactor struct {
+	blockRequests []int64
+}
+
+func (r *fakeBcReactor) sendBlockRequest(peerID p2p.ID, height int64) error {
+	r.blockRequests = append(r.blockRequests, height)
+	return nil
+}
+
+func (r *fakeBcReactor) sendPeerError(err error, peerID p2p.ID) {}
+
+func (r *fakeBcReactor) sendSnapshotRequest(peerID p2p.ID) error { return nil }
+
+func (r *fakeBcReactor) sendChunkRequest(peerID p2p.ID, i int32) error { return nil }
+
+func newTestPool(t *testing.T, height int64) (*blockPool, *fakeBcReactor) {
+	r := &fakeBcReactor{}
+	pool := NewBlockPool(height, r)
+	pool.SetLogger(log.NewNopLogger())
+	return pool, r
+}
+
+// TestMakeNextRequestsRespectsPeerConnWait guards the peerConnWait grace
+// period added in this series: MakeNextRequests must be a no-op until it
+// elapses, even with peers and pending heights available.
+func TestMakeNextRequestsRespectsPeerConnWait(t *testing.T) {
+	defer func(d time.Duration) { peerConnWait = d }(peerConnWait)
+	peerConnWait = 50 * time.Millisecond
+
+	pool, r := newTestPool(t, 1)
+	require.NoError(t, pool.UpdatePeer("p1", 100))
+
+	pool.MakeNextRequests(10)
+	assert.Empty(t, r.blockRequests, "requests should be withheld during peerConnWait")
+
+	time.Sleep(60 * time.Millisecond)
+	pool.MakeNextRequests(10)
+	assert.NotEmpty(t, r.blockRequests, "requests should proceed once peerConnWait has elapsed")
+}
+
+// TestRetryTimedOutRequestsUsesPeerTimeout guards against reintroducing a
+// second, redundant retry-duration var: retryTimedOutRequests must honor
+// peerTimeout, and shrinking it in a test must be enough to trigger a
+// retry.
+func TestRetryTimedOutRequestsUsesPeerTimeout(t *testing.T) {
+	defer func(d time.Duration) { peerTimeout = d }(peerTimeout)
+	peerTimeout = 10 * time.Millisecond
+
+	pool, _ := newTestPool(t, 1)
+	require.NoError(t, pool.UpdatePeer("p1", 100))
+	pool.blocks[1] = "p1"
+	pool.peers["p1"].RequestSent(1)
+	pool.requestSentAt[1] = time.Now().Add(-20 * time.Millisecond)
+
+	pool.retryTimedOutRequests()
+
+	_, stillPending := pool.requestSentAt[1]
+	assert.False(t, stillPending, "timed out request should have been cleared")
+	_, rescheduled := pool.plannedRequests[1]
+	assert.True(t, rescheduled, "timed out request should have been rescheduled")
+	assert.Equal(t, 1, pool.peers["p1"].timeoutCount)
+}
+
+// TestRescheduleRequestFreesPendingSlot guards against a peer's
+// NumPendingBlockRequests staying permanently pinned at maxRequestsPerPeer
+// after enough of its requests time out: rescheduleRequest must decrement
+// it, the same as the AddBlock/AddChunk success paths do.
+func TestRescheduleRequestFreesPendingSlot(t *testing.T) {
+	pool, _ := newTestPool(t, 1)
+	require.NoError(t, pool.UpdatePeer("p1", 100))
+	pool.blocks[5] = "p1"
+	pool.peers["p1"].RequestSent(5)
+	require.Equal(t, 1, pool.peers["p1"].NumPendingBlockRequests)
+
+	pool.rescheduleRequest("p1", 5)
+
+	assert.Equal(t, 0, pool.peers["p1"].NumPendingBlockRequests,
+		"rescheduling a timed-out request must free the peer's pending slot")
+}
+
+// TestAddBlockClearsRequestSentAt guards against the bug where a block
+// delivered ahead of pool.Height, and therefore left in pool.blocks for a
+// while, would later be mistaken by retryTimedOutRequests for a timed-out
+// request and discarded even though it had already arrived.
+func TestAddBlockClearsRequestSentAt(t *testing.T) {
+	pool, _ := newTestPool(t, 1)
+	require.NoError(t, pool.UpdatePeer("p1", 100))
+	pool.blocks[5] = "p1"
+	pool.peers["p1"].RequestSent(5)
+	pool.requestSentAt[5] = time.Now()
+
+	block := &types.Block{Header: types.Header{Height: 5}}
+	require.NoError(t, pool.AddBlock("p1", block, 100))
+
+	_, stillTracked := pool.requestSentAt[5]
+	assert.False(t, stillTracked, "requestSentAt should be cleared once the block arrives")
+}
+
+// TestCheckRateFlagsPeerThatWentSilent guards against CheckRate exempting a
+// peer whose rate has been trimmed to 0 by the monitor's rolling window
+// after it stopped sending: once a peer has received bytes at all, a
+// current rate of 0 must count as slow, not as "not yet judged".
+func TestCheckRateFlagsPeerThatWentSilent(t *testing.T) {
+	peer := NewBPPeer("p1", 100, nil, log.NewNopLogger())
+	require.NoError(t, peer.AddBlock(&types.Block{Header: types.Header{Height: 1}}, 1))
+
+	time.Sleep(1100 * time.Millisecond) // outlives recvMonitor's 1s rolling window
+
+	assert.Error(t, peer.CheckRate(), "a peer that received data and then went silent should be flagged as slow")
+}
+
+// TestCheckRateExemptsBrandNewPeer guards the opposite case: a peer that
+// hasn't received any bytes at all yet (no samples to judge a rate from)
+// must not be disconnected as slow.
+func TestCheckRateExemptsBrandNewPeer(t *testing.T) {
+	peer := NewBPPeer("p1", 100, nil, log.NewNopLogger())
+	assert.NoError(t, peer.CheckRate())
+}
@@ -0,0 +1,25 @@
+package blockchainexp
+
+import "github.com/tendermint/tendermint/p2p"
+
+// bcReactor is the subset of the blockchain reactor the pool needs in order
+// to talk to peers. It lets the pool stay free of any p2p.Switch/Peer
+// plumbing while still being able to send requests and report misbehavior.
+type bcReactor interface {
+	// sendBlockRequest asks peerID for the block at height. It returns
+	// errNilPeerForBlockRequest if the peer is no longer known to the
+	// switch, or errSendQueueFull if the peer's send queue is backed up.
+	sendBlockRequest(peerID p2p.ID, height int64) error
+
+	// sendPeerError reports err as the reason peerID is being penalized or
+	// disconnected.
+	sendPeerError(err error, peerID p2p.ID)
+
+	// sendSnapshotRequest asks peerID for the manifest of the snapshot it
+	// advertised in its status response.
+	sendSnapshotRequest(peerID p2p.ID) error
+
+	// sendChunkRequest asks peerID for chunk chunkIndex of the snapshot
+	// currently being fetched.
+	sendChunkRequest(peerID p2p.ID, chunkIndex int32) error
+}
@@ -0,0 +1,68 @@
+package blockchainexp
+
+import (
+	"bytes"
+	"crypto/sha256"
+
+	"github.com/tendermint/tendermint/p2p"
+)
+
+// snapshotManifest describes a state-sync snapshot advertised by a peer:
+// the height and application format it was taken at, how many chunks it is
+// split into, and the per-chunk hashes used to verify each chunk as it
+// arrives, without needing the whole snapshot assembled first.
+type snapshotManifest struct {
+	Height      int64
+	Format      uint32
+	NumChunks   int32
+	ChunkHashes [][]byte // leaf hash for each chunk, indexed by chunk number
+}
+
+// verifyChunk reports whether chunk data hashes to the manifest's recorded
+// hash for chunkIndex.
+func (m snapshotManifest) verifyChunk(chunkIndex int32, chunk []byte) bool {
+	if chunkIndex < 0 || int(chunkIndex) >= len(m.ChunkHashes) {
+		return false
+	}
+	sum := sha256.Sum256(chunk)
+	return bytes.Equal(sum[:], m.ChunkHashes[chunkIndex])
+}
+
+// snapshotState tracks the pool's progress through a SnapshotMode bootstrap:
+// the manifest being fetched, which chunks have been requested from which
+// peer, and which have been received and verified.
+type snapshotState struct {
+	manifest snapshotManifest
+	pending  map[int32]p2p.ID // chunk index -> peer it was requested from
+	chunks   map[int32][]byte // verified chunks received so far
+}
+
+func newSnapshotState(manifest snapshotManifest) *snapshotState {
+	return &snapshotState{
+		manifest: manifest,
+		pending:  make(map[int32]p2p.ID),
+		chunks:   make(map[int32][]byte),
+	}
+}
+
+// done reports whether every chunk in the manifest has been received and
+// verified.
+func (s *snapshotState) done() bool {
+	return len(s.chunks) == int(s.manifest.NumChunks)
+}
+
+// nextChunksNeeded returns up to maxNumRequests chunk indexes that have
+// neither been received nor already have a pending request.
+func (s *snapshotState) nextChunksNeeded(maxNumRequests int) []int32 {
+	needed := make([]int32, 0, maxNumRequests)
+	for i := int32(0); i < s.manifest.NumChunks && len(needed) < maxNumRequests; i++ {
+		if _, got := s.chunks[i]; got {
+			continue
+		}
+		if _, pending := s.pending[i]; pending {
+			continue
+		}
+		needed = append(needed, i)
+	}
+	return needed
+}
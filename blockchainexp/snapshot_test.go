@@ -0,0 +1,135 @@
+package blockchainexp
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/p2p"
+)
+
+func testManifest(numChunks int32) snapshotManifest {
+	hashes := make([][]byte, numChunks)
+	for i := range hashes {
+		sum := sha256.Sum256([]byte{byte(i)})
+		hashes[i] = sum[:]
+	}
+	return snapshotManifest{Height: 100, Format: 1, NumChunks: numChunks, ChunkHashes: hashes}
+}
+
+// TestRemovePeerFreesPendingChunks guards against SnapshotMode hanging
+// forever: if the peer a chunk request is outstanding against gets
+// removed, that chunk must become available to request again, not stay
+// stuck in snapshot.pending.
+func TestRemovePeerFreesPendingChunks(t *testing.T) {
+	pool, _ := newTestPool(t, 1)
+	require.NoError(t, pool.UpdatePeer("p1", 100))
+	require.NoError(t, pool.UpdatePeerSnapshot("p1", 100, 1))
+
+	pool.StartSnapshotMode(testManifest(2))
+	require.True(t, pool.sendChunkRequest(0))
+	assert.Contains(t, pool.snapshot.pending, int32(0))
+
+	pool.RemovePeer("p1", nil)
+
+	assert.NotContains(t, pool.snapshot.pending, int32(0), "pending chunk should be freed when its peer is removed")
+	needed := pool.snapshot.nextChunksNeeded(2)
+	assert.Contains(t, needed, int32(0), "freed chunk should be offered again")
+}
+
+// TestAddChunkCompletesSnapshotMode guards the happy path: once every
+// chunk has arrived and verified, the pool leaves SnapshotMode and resumes
+// block fast-sync from snapshot.Height+1.
+func TestAddChunkCompletesSnapshotMode(t *testing.T) {
+	pool, _ := newTestPool(t, 1)
+	require.NoError(t, pool.UpdatePeer("p1", 100))
+	require.NoError(t, pool.UpdatePeerSnapshot("p1", 100, 1))
+
+	manifest := testManifest(2)
+	pool.StartSnapshotMode(manifest)
+
+	require.NoError(t, pool.AddChunk("p1", 0, []byte{0}))
+	require.NoError(t, pool.AddChunk("p1", 1, []byte{1}))
+
+	assert.False(t, pool.SnapshotMode)
+	assert.Equal(t, manifest.Height+1, pool.Height)
+}
+
+// TestSendChunkRequestPrefersHigherScoringPeer guards against
+// sendChunkRequest reverting to map-order peer iteration: given two
+// snapshot-advertising peers, it must pick the one with the better
+// recv-rate/pending/timeout score, not whichever the map happens to
+// yield first.
+func TestSendChunkRequestPrefersHigherScoringPeer(t *testing.T) {
+	pool, _ := newTestPool(t, 1)
+	require.NoError(t, pool.UpdatePeer("slow", 100))
+	require.NoError(t, pool.UpdatePeer("fast", 100))
+	require.NoError(t, pool.UpdatePeerSnapshot("slow", 100, 1))
+	require.NoError(t, pool.UpdatePeerSnapshot("fast", 100, 1))
+	pool.peers["fast"].ewmaRecvRate = 1_000_000
+	pool.peers["slow"].ewmaRecvRate = 10
+
+	pool.StartSnapshotMode(testManifest(1))
+	require.True(t, pool.sendChunkRequest(0))
+
+	assert.Equal(t, p2p.ID("fast"), pool.snapshot.pending[0])
+}
+
+// TestRetryTimedOutChunkRequestsFreesStuckChunk guards against
+// SnapshotMode hanging forever on a peer that accepted a chunk request and
+// never replied: nextChunksNeeded only re-offers a chunk once it's no
+// longer pending, so something has to time it out, the same way
+// retryTimedOutRequests does for block requests.
+func TestRetryTimedOutChunkRequestsFreesStuckChunk(t *testing.T) {
+	defer func(d time.Duration) { peerTimeout = d }(peerTimeout)
+	peerTimeout = 10 * time.Millisecond
+
+	pool, _ := newTestPool(t, 1)
+	require.NoError(t, pool.UpdatePeer("p1", 100))
+	require.NoError(t, pool.UpdatePeerSnapshot("p1", 100, 1))
+
+	pool.StartSnapshotMode(testManifest(2))
+	require.True(t, pool.sendChunkRequest(0))
+
+	pool.chunkRequestSentAt[0] = time.Now().Add(-20 * time.Millisecond)
+	pool.MakeNextChunkRequests(2)
+
+	assert.NotContains(t, pool.snapshot.pending, int32(0), "timed out chunk request should be freed")
+	assert.Equal(t, 1, pool.peers["p1"].timeoutCount)
+}
+
+// TestMakeNextRequestsNoopDuringSnapshotMode guards against the pool
+// downloading ordinary blocks at the pre-snapshot height while a state-sync
+// snapshot is being fetched and verified; those blocks would just be
+// discarded the moment pool.Height jumps to snapshot.Height+1.
+func TestMakeNextRequestsNoopDuringSnapshotMode(t *testing.T) {
+	pool, r := newTestPool(t, 1)
+	peerConnWaitBackup := peerConnWait
+	peerConnWait = 0
+	defer func() { peerConnWait = peerConnWaitBackup }()
+
+	require.NoError(t, pool.UpdatePeer("p1", 100))
+	pool.StartSnapshotMode(testManifest(1))
+
+	pool.MakeNextRequests(10)
+
+	assert.Empty(t, r.blockRequests, "block requests must be withheld while SnapshotMode is active")
+}
+
+// TestAddChunkRejectsBadHash guards the reject/retry behavior the request
+// asked for: a chunk that doesn't match the manifest's hash is dropped and
+// its peer removed, rather than silently accepted.
+func TestAddChunkRejectsBadHash(t *testing.T) {
+	pool, _ := newTestPool(t, 1)
+	require.NoError(t, pool.UpdatePeer("p1", 100))
+	require.NoError(t, pool.UpdatePeerSnapshot("p1", 100, 1))
+
+	pool.StartSnapshotMode(testManifest(2))
+
+	err := pool.AddChunk("p1", 0, []byte("not the right bytes"))
+	assert.Error(t, err)
+	assert.NotContains(t, pool.snapshot.chunks, int32(0))
+}
@@ -0,0 +1,69 @@
+package evidence
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// dedupFilterBits and dedupFilterHashes size a bloom filter for roughly
+// 100k pieces of evidence at a false-positive rate around 1%. Evidence
+// volume is expected to be tiny relative to block or tx gossip, so this is
+// generous headroom rather than a tuned budget.
+const (
+	dedupFilterBits   = 1 << 20 // 131072 bytes
+	dedupFilterHashes = 7
+)
+
+// gossipDedup is a bloom-filter-backed "have we seen this evidence hash
+// before" check. It sits in front of the evidence DB so the gossip reactor
+// can cheaply reject evidence it has already stored or already rejected,
+// without a disk read on every relayed message. False positives just cost
+// an extra DB lookup; false negatives never happen.
+type gossipDedup struct {
+	mtx  sync.Mutex
+	bits []uint64
+}
+
+func newGossipDedup() *gossipDedup {
+	return &gossipDedup{bits: make([]uint64, dedupFilterBits/64)}
+}
+
+// Seen reports whether hash has (probably) been added before.
+func (d *gossipDedup) Seen(hash []byte) bool {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	for _, idx := range d.indexes(hash) {
+		if d.bits[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Add records hash as seen.
+func (d *gossipDedup) Add(hash []byte) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	for _, idx := range d.indexes(hash) {
+		d.bits[idx/64] |= 1 << (idx % 64)
+	}
+}
+
+// indexes computes dedupFilterHashes bit positions for hash using the
+// double-hashing technique (Kirsch-Mitzenmacher), which needs only two
+// underlying hash computations to simulate k independent hash functions.
+func (d *gossipDedup) indexes(hash []byte) []uint64 {
+	h1 := fnv.New64a()
+	h1.Write(hash)
+	sum1 := h1.Sum64()
+
+	h2 := fnv.New64()
+	h2.Write(hash)
+	sum2 := h2.Sum64()
+
+	idxs := make([]uint64, dedupFilterHashes)
+	for i := 0; i < dedupFilterHashes; i++ {
+		idxs[i] = (sum1 + uint64(i)*sum2) % dedupFilterBits
+	}
+	return idxs
+}
@@ -18,3 +18,47 @@ type ErrEvidenceAlreadyStored struct{}
 func (e ErrEvidenceAlreadyStored) Error() string {
 	return fmt.Sprint("evidence is already stored")
 }
+
+// ErrEvidenceExpired indicates that the evidence is too old, relative to the
+// consensus params in effect at Height, to be accepted.
+type ErrEvidenceExpired struct {
+	Height       int64
+	MaxAgeBlocks int64
+}
+
+func (e ErrEvidenceExpired) Error() string {
+	return fmt.Sprintf("evidence from height %d is too old; evidence can only be submitted within %d blocks",
+		e.Height, e.MaxAgeBlocks)
+}
+
+// ErrEvidenceFromFuture indicates that the evidence references a height or
+// time the node hasn't reached yet, and so cannot be verified.
+type ErrEvidenceFromFuture struct {
+	Height int64
+}
+
+func (e ErrEvidenceFromFuture) Error() string {
+	return fmt.Sprintf("evidence from height %d is from the future", e.Height)
+}
+
+// ErrInvalidEvidenceSignature indicates that the signature(s) attached to
+// the evidence failed verification against the suspected validator's key.
+type ErrInvalidEvidenceSignature struct {
+	Reason error
+}
+
+func (e ErrInvalidEvidenceSignature) Error() string {
+	return fmt.Sprintf("evidence signature is invalid: %v", e.Reason)
+}
+
+// ErrEvidenceOverflow indicates that adding the evidence would exceed the
+// maximum total evidence bytes allowed per block.
+type ErrEvidenceOverflow struct {
+	MaxBytes int64
+	GotBytes int64
+}
+
+func (e ErrEvidenceOverflow) Error() string {
+	return fmt.Sprintf("evidence overflows the maximum allowed size: %d bytes, got %d bytes",
+		e.MaxBytes, e.GotBytes)
+}
@@ -0,0 +1,131 @@
+package evidence
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+// maxEvidenceBytes bounds how much evidence, by serialized size, the pool
+// will hold onto at once.
+const maxEvidenceBytes = 1024 * 1024 // 1MB
+
+// EvidencePoolState is the subset of consensus state AddEvidence needs to
+// decide whether a piece of evidence is too old, too new, or otherwise
+// acceptable.
+type EvidencePoolState interface {
+	GetHeight() int64
+	GetBlockTime() time.Time
+	GetEvidenceParams() types.EvidenceParams
+}
+
+// EvidencePool is the node-wide evidence pool: it verifies, de-duplicates,
+// stores, and eventually prunes evidence of validator misbehavior. It is
+// the concrete type behind rpc/core's evidencePool variable.
+type EvidencePool struct {
+	logger log.Logger
+
+	store     EvidenceStore
+	state     EvidencePoolState
+	verifiers *verifierRegistry
+	dedup     *gossipDedup
+	pruner    *evidencePruner
+
+	totalEvidenceBytes int64
+}
+
+// NewEvidencePool creates an EvidencePool backed by store, using state to
+// look up the current height and evidence params. The default verifiers
+// for duplicate-vote and light-client-attack evidence are registered
+// automatically; call RegisterVerifier to add or override one.
+func NewEvidencePool(store EvidenceStore, state EvidencePoolState) *EvidencePool {
+	verifiers := newVerifierRegistry()
+	registerDefaultVerifiers(verifiers)
+
+	return &EvidencePool{
+		logger:    log.NewNopLogger(),
+		store:     store,
+		state:     state,
+		verifiers: verifiers,
+		dedup:     newGossipDedup(),
+		pruner:    newEvidencePruner(store),
+	}
+}
+
+// SetLogger sets the logger of the pool.
+func (evpool *EvidencePool) SetLogger(l log.Logger) {
+	evpool.logger = l
+}
+
+// RegisterVerifier registers v as the verifier run on all evidence of the
+// concrete type of sample, before it is admitted to the pool. It overrides
+// any verifier already registered for that type.
+func (evpool *EvidencePool) RegisterVerifier(sample types.Evidence, v EvidenceVerifier) {
+	evpool.verifiers.RegisterVerifier(sample, v)
+}
+
+// AddEvidence runs ev through the gossip dedup filter, age checks, and the
+// registered EvidenceVerifier for its type, before persisting it to the
+// store. It returns ErrEvidenceAlreadyStored if ev (or its hash) was
+// already seen, ErrEvidenceFromFuture/ErrEvidenceExpired if ev falls
+// outside the acceptable age window, ErrInvalidEvidenceSignature if
+// verification fails, or ErrEvidenceOverflow if the pool is already at
+// its byte budget.
+func (evpool *EvidencePool) AddEvidence(ev types.Evidence) error {
+	hash := ev.Hash()
+	if evpool.dedup.Seen(hash) {
+		return ErrEvidenceAlreadyStored{}
+	}
+
+	height := evpool.state.GetHeight()
+	if ev.Height() > height {
+		return ErrEvidenceFromFuture{Height: ev.Height()}
+	}
+
+	params := evpool.state.GetEvidenceParams()
+	// Evidence must exceed both the height and time bounds to be treated
+	// as expired, matching evidencePruner.PruneExpired: judging expiry on
+	// height alone would reject evidence that is still legitimately within
+	// MaxAgeDuration on any chain whose block times vary from the
+	// theoretical minimum.
+	ageBlocks := height - ev.Height()
+	ageDuration := evpool.state.GetBlockTime().Sub(ev.Time())
+	if ageBlocks > params.MaxAgeNumBlocks && ageDuration > params.MaxAgeDuration {
+		return ErrEvidenceExpired{Height: ev.Height(), MaxAgeBlocks: params.MaxAgeNumBlocks}
+	}
+
+	if err := evpool.verifiers.Verify(ev); err != nil {
+		return ErrInvalidEvidenceSignature{Reason: err}
+	}
+
+	evBytes := int64(len(ev.Bytes()))
+	if evpool.totalEvidenceBytes+evBytes > maxEvidenceBytes {
+		return ErrEvidenceOverflow{MaxBytes: maxEvidenceBytes, GotBytes: evpool.totalEvidenceBytes + evBytes}
+	}
+
+	if err := evpool.store.AddEvidence(ev); err != nil {
+		return fmt.Errorf("failed to store evidence: %w", err)
+	}
+
+	evpool.dedup.Add(hash)
+	evpool.totalEvidenceBytes += evBytes
+	return nil
+}
+
+// Update is called after a block is committed so the pool can prune
+// evidence that has fallen outside the age window as of the new height.
+func (evpool *EvidencePool) Update(height int64, blockTime time.Time, params types.EvidenceParams) {
+	pruned := evpool.pruner.PruneExpired(height, blockTime, params)
+	if pruned > 0 {
+		evpool.logger.Info("pruned expired evidence", "height", height, "pruned", pruned)
+		// Recompute the byte budget from what's actually left in the
+		// store; PruneExpired doesn't know about totalEvidenceBytes.
+		var remaining int64
+		for _, ev := range evpool.store.ListEvidence() {
+			remaining += int64(len(ev.Bytes()))
+		}
+		evpool.totalEvidenceBytes = remaining
+	}
+}
@@ -0,0 +1,154 @@
+package evidence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// fakeEvidence is a minimal types.Evidence used to drive EvidencePool in
+// isolation, without needing real validator signatures.
+type fakeEvidence struct {
+	height   int64
+	time     time.Time
+	hash     []byte
+	basicErr error
+}
+
+func (e *fakeEvidence) Height() int64             { return e.height }
+func (e *fakeEvidence) Time() time.Time           { return e.time }
+func (e *fakeEvidence) Hash() []byte              { return e.hash }
+func (e *fakeEvidence) Bytes() []byte             { return e.hash }
+func (e *fakeEvidence) ValidateBasic() error      { return e.basicErr }
+func (e *fakeEvidence) Equal(types.Evidence) bool { return false }
+func (e *fakeEvidence) String() string            { return "fakeEvidence" }
+
+type fakeStore struct {
+	byHash map[string]types.Evidence
+}
+
+func newFakeStore() *fakeStore { return &fakeStore{byHash: make(map[string]types.Evidence)} }
+
+func (s *fakeStore) AddEvidence(ev types.Evidence) error {
+	s.byHash[string(ev.Hash())] = ev
+	return nil
+}
+
+func (s *fakeStore) ListEvidence() []types.Evidence {
+	out := make([]types.Evidence, 0, len(s.byHash))
+	for _, ev := range s.byHash {
+		out = append(out, ev)
+	}
+	return out
+}
+
+func (s *fakeStore) DeleteEvidence(ev types.Evidence) error {
+	delete(s.byHash, string(ev.Hash()))
+	return nil
+}
+
+type fakeState struct {
+	height    int64
+	blockTime time.Time
+	params    types.EvidenceParams
+}
+
+func (s *fakeState) GetHeight() int64                        { return s.height }
+func (s *fakeState) GetBlockTime() time.Time                 { return s.blockTime }
+func (s *fakeState) GetEvidenceParams() types.EvidenceParams { return s.params }
+
+func newTestEvidencePool(height int64) (*EvidencePool, *fakeStore) {
+	store := newFakeStore()
+	state := &fakeState{
+		height:    height,
+		blockTime: time.Now(),
+		params:    types.EvidenceParams{MaxAgeNumBlocks: 10, MaxAgeDuration: time.Hour},
+	}
+	pool := NewEvidencePool(store, state)
+	// fakeEvidence has no default verifier registered (it isn't one of
+	// the types registerDefaultVerifiers knows about), so tests register
+	// one explicitly: it just defers to ValidateBasic, which is enough to
+	// exercise the dedup/age/overflow paths without real signatures.
+	pool.RegisterVerifier(&fakeEvidence{}, duplicateVoteVerifier{})
+	return pool, store
+}
+
+// TestAddEvidenceStoresAndDedups guards the end-to-end wiring this request
+// was supposed to deliver: a valid piece of evidence is verified, stored,
+// and a second submission of the same evidence is rejected as a duplicate
+// rather than re-verified and re-stored.
+func TestAddEvidenceStoresAndDedups(t *testing.T) {
+	pool, store := newTestEvidencePool(100)
+	ev := &fakeEvidence{height: 95, time: time.Now(), hash: []byte("ev1")}
+
+	require.NoError(t, pool.AddEvidence(ev))
+	assert.Len(t, store.ListEvidence(), 1)
+
+	err := pool.AddEvidence(ev)
+	assert.IsType(t, ErrEvidenceAlreadyStored{}, err)
+}
+
+// TestAddEvidenceRejectsExpired guards the ErrEvidenceExpired path: it must
+// actually be reachable from AddEvidence, not just declared. Evidence is
+// only expired once it exceeds both the height and time bounds, matching
+// evidencePruner.PruneExpired.
+func TestAddEvidenceRejectsExpired(t *testing.T) {
+	pool, _ := newTestEvidencePool(100)
+	ev := &fakeEvidence{height: 10, time: time.Now().Add(-2 * time.Hour), hash: []byte("ev2")}
+
+	err := pool.AddEvidence(ev)
+	require.Error(t, err)
+	assert.IsType(t, ErrEvidenceExpired{}, err)
+}
+
+// TestAddEvidenceAcceptsOldHeightWithinTimeWindow guards against expiry
+// being judged on block height alone: evidence far enough in the past by
+// height, but still within MaxAgeDuration, must be accepted, since a chain
+// whose block times run slower than the theoretical minimum would
+// otherwise have legitimate evidence rejected.
+func TestAddEvidenceAcceptsOldHeightWithinTimeWindow(t *testing.T) {
+	pool, _ := newTestEvidencePool(100)
+	ev := &fakeEvidence{height: 10, time: time.Now(), hash: []byte("ev6")}
+
+	assert.NoError(t, pool.AddEvidence(ev))
+}
+
+// TestAddEvidenceRejectsFromFuture guards the ErrEvidenceFromFuture path.
+func TestAddEvidenceRejectsFromFuture(t *testing.T) {
+	pool, _ := newTestEvidencePool(100)
+	ev := &fakeEvidence{height: 200, time: time.Now(), hash: []byte("ev3")}
+
+	err := pool.AddEvidence(ev)
+	require.Error(t, err)
+	assert.IsType(t, ErrEvidenceFromFuture{}, err)
+}
+
+// TestAddEvidenceRejectsInvalid guards that a verifier failure surfaces as
+// ErrInvalidEvidenceSignature, not a raw/opaque error.
+func TestAddEvidenceRejectsInvalid(t *testing.T) {
+	pool, _ := newTestEvidencePool(100)
+	ev := &fakeEvidence{height: 95, time: time.Now(), hash: []byte("ev4"), basicErr: assert.AnError}
+
+	err := pool.AddEvidence(ev)
+	require.Error(t, err)
+	assert.IsType(t, ErrInvalidEvidenceSignature{}, err)
+}
+
+// TestUpdatePrunesExpiredEvidence guards that Update (the hook a consensus
+// state machine calls after committing a block) actually drives
+// evidencePruner.PruneExpired, rather than it being dead code nothing
+// calls.
+func TestUpdatePrunesExpiredEvidence(t *testing.T) {
+	pool, store := newTestEvidencePool(100)
+	ev := &fakeEvidence{height: 95, time: time.Now().Add(-2 * time.Hour), hash: []byte("ev5")}
+	require.NoError(t, pool.AddEvidence(ev))
+	require.Len(t, store.ListEvidence(), 1)
+
+	pool.Update(120, time.Now(), types.EvidenceParams{MaxAgeNumBlocks: 10, MaxAgeDuration: time.Hour})
+
+	assert.Empty(t, store.ListEvidence())
+}
@@ -0,0 +1,48 @@
+package evidence
+
+import (
+	"time"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// evidencePruner removes evidence from the pool once it falls outside the
+// age window allowed by the consensus evidence params, so the pool doesn't
+// grow without bound as the chain advances.
+type evidencePruner struct {
+	store EvidenceStore
+}
+
+// EvidenceStore is the subset of the evidence DB the pool and pruner need:
+// persisting new evidence, listing what's stored, and deleting entries
+// that have expired.
+type EvidenceStore interface {
+	AddEvidence(ev types.Evidence) error
+	ListEvidence() []types.Evidence
+	DeleteEvidence(ev types.Evidence) error
+}
+
+func newEvidencePruner(store EvidenceStore) *evidencePruner {
+	return &evidencePruner{store: store}
+}
+
+// PruneExpired deletes all evidence older than params.EvidenceParams'
+// MaxAgeNumBlocks and MaxAgeDuration, given the current height and time.
+// Evidence must be older than both bounds to be pruned, matching how
+// evidence expiry is checked during block validation.
+func (p *evidencePruner) PruneExpired(height int64, now time.Time, params types.EvidenceParams) (pruned int) {
+	for _, ev := range p.store.ListEvidence() {
+		age := height - ev.Height()
+		if age <= params.MaxAgeNumBlocks {
+			continue
+		}
+		if now.Sub(ev.Time()) <= params.MaxAgeDuration {
+			continue
+		}
+		if err := p.store.DeleteEvidence(ev); err != nil {
+			continue
+		}
+		pruned++
+	}
+	return pruned
+}
@@ -0,0 +1,48 @@
+package evidence
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// EvidenceVerifier checks that a piece of evidence is internally consistent
+// and correctly signed before it is admitted to the pool. Implementations
+// are specific to one evidence type (duplicate-vote, light-client-attack,
+// lunatic, amnesia, ...).
+type EvidenceVerifier interface {
+	// Verify returns nil if ev is well-formed and its signature(s) check
+	// out, or a descriptive error (typically ErrInvalidEvidenceSignature)
+	// otherwise.
+	Verify(ev types.Evidence) error
+}
+
+// verifierRegistry maps an evidence type to the verifier responsible for it.
+// It is not safe for concurrent writes; all verifiers are expected to be
+// registered during initialization, before the pool starts serving
+// AddEvidence calls.
+type verifierRegistry struct {
+	verifiers map[reflect.Type]EvidenceVerifier
+}
+
+func newVerifierRegistry() *verifierRegistry {
+	return &verifierRegistry{verifiers: make(map[reflect.Type]EvidenceVerifier)}
+}
+
+// RegisterVerifier registers v as the verifier for all evidence of the
+// concrete type of sample. It overwrites any previously registered
+// verifier for that type.
+func (r *verifierRegistry) RegisterVerifier(sample types.Evidence, v EvidenceVerifier) {
+	r.verifiers[reflect.TypeOf(sample)] = v
+}
+
+// Verify looks up the verifier registered for ev's concrete type and runs
+// it. If no verifier is registered for that type, ev is rejected.
+func (r *verifierRegistry) Verify(ev types.Evidence) error {
+	v, ok := r.verifiers[reflect.TypeOf(ev)]
+	if !ok {
+		return errInvalidEvidence{Reason: fmt.Errorf("no verifier registered for evidence type %T", ev)}
+	}
+	return v.Verify(ev)
+}
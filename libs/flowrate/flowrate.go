@@ -0,0 +1,113 @@
+// Package flowrate provides a minimal rolling-window byte-rate monitor used
+// to track how fast a peer is sending us data. It is a small, purpose-built
+// subset of the rate-limiting ideas in mxk/go-flowrate, kept local so the
+// blockchainexp pool does not need an external dependency for something this
+// simple.
+package flowrate
+
+import (
+	"sync"
+	"time"
+)
+
+// Status is a snapshot of a Monitor's state at the time Status() was called.
+type Status struct {
+	// CurRate is the current transfer rate in bytes per second, averaged
+	// over the trailing sampling window.
+	CurRate int64
+	// AvgRate is the average transfer rate in bytes per second since the
+	// monitor was started.
+	AvgRate int64
+	// Bytes is the total number of bytes recorded since the monitor was
+	// started.
+	Bytes int64
+	// Duration is how long the monitor has been running.
+	Duration time.Duration
+}
+
+// Monitor tracks the rate at which bytes are received over a rolling
+// sampling window. It is safe for concurrent use.
+type Monitor struct {
+	mtx     sync.Mutex
+	window  time.Duration
+	start   time.Time
+	total   int64
+	samples []sample
+}
+
+type sample struct {
+	at    time.Time
+	bytes int64
+}
+
+// New creates a Monitor that reports CurRate over the given rolling window.
+func New(window time.Duration) *Monitor {
+	if window <= 0 {
+		window = time.Second
+	}
+	return &Monitor{window: window, start: time.Now()}
+}
+
+// Update records n newly received bytes and returns the current rate in
+// bytes per second, computed over the trailing sampling window.
+func (m *Monitor) Update(n int) int64 {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	now := time.Now()
+	m.total += int64(n)
+	m.samples = append(m.samples, sample{at: now, bytes: int64(n)})
+	m.trim(now)
+
+	return m.curRate(now)
+}
+
+// Status returns a snapshot of the monitor's current rate and totals.
+func (m *Monitor) Status() Status {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	now := time.Now()
+	m.trim(now)
+	dur := now.Sub(m.start)
+	var avg int64
+	if secs := dur.Seconds(); secs > 0 {
+		avg = int64(float64(m.total) / secs)
+	}
+	return Status{
+		CurRate:  m.curRate(now),
+		AvgRate:  avg,
+		Bytes:    m.total,
+		Duration: dur,
+	}
+}
+
+// trim drops samples that have fallen out of the rolling window. Caller
+// must hold m.mtx.
+func (m *Monitor) trim(now time.Time) {
+	cutoff := now.Add(-m.window)
+	i := 0
+	for ; i < len(m.samples); i++ {
+		if m.samples[i].at.After(cutoff) {
+			break
+		}
+	}
+	m.samples = m.samples[i:]
+}
+
+// curRate computes bytes/sec over the samples still in the window. Caller
+// must hold m.mtx.
+func (m *Monitor) curRate(now time.Time) int64 {
+	if len(m.samples) == 0 {
+		return 0
+	}
+	var sum int64
+	for _, s := range m.samples {
+		sum += s.bytes
+	}
+	elapsed := now.Sub(m.samples[0].at).Seconds()
+	if elapsed < 1 {
+		elapsed = 1
+	}
+	return int64(float64(sum) / elapsed)
+}
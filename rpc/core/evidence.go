@@ -1,6 +1,8 @@
 package core
 
 import (
+	"fmt"
+
 	"github.com/tendermint/tendermint/evidence"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
@@ -11,9 +13,19 @@ import (
 // More: https://docs.tendermint.com/master/rpc/#/Info/broadcast_evidence
 func BroadcastEvidence(ctx *rpctypes.Context, ev types.Evidence) (*ctypes.ResultBroadcastEvidence, error) {
 	err := evidencePool.AddEvidence(ev)
-	switch err.(type) {
+	switch err := err.(type) {
 	case nil, evidence.ErrEvidenceAlreadyStored:
 		return &ctypes.ResultBroadcastEvidence{Hash: ev.Hash()}, nil
+	case evidence.ErrEvidenceExpired:
+		// Distinguished from other rejections so clients know resubmitting
+		// the same evidence will never succeed.
+		return nil, fmt.Errorf("evidence rejected, too old: %w", err)
+	case evidence.ErrEvidenceFromFuture:
+		return nil, fmt.Errorf("evidence rejected, from the future: %w", err)
+	case evidence.ErrInvalidEvidenceSignature:
+		return nil, fmt.Errorf("evidence rejected, invalid signature: %w", err)
+	case evidence.ErrEvidenceOverflow:
+		return nil, fmt.Errorf("evidence rejected, pool is full: %w", err)
 	default:
 		return nil, err
 	}